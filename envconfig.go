@@ -5,10 +5,11 @@
 package envconfig
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/url"
-	"os"
 	"reflect"
 	"strconv"
 	"strings"
@@ -40,6 +41,15 @@ func (e *RequiredError) Error() string {
 	return fmt.Sprintf("envconfig.Process: required key %[1]s not found", e.KeyName)
 }
 
+// Decoder is implemented by types that know how to deserialize themselves
+// from the string form of an environment variable, such as time.Duration,
+// net.IP, or a custom enum. A field whose type (or pointer to its type)
+// implements Decoder is handed the resolved value directly, bypassing the
+// built-in scalar conversions.
+type Decoder interface {
+	Decode(value string) error
+}
+
 type MultiError []error
 
 func (e MultiError) Error() string {
@@ -53,86 +63,221 @@ func (e MultiError) Error() string {
 // Process parses the environment and loads the contents into the matching
 // elements inside the provided spec.
 func Process(prefix string, spec interface{}) error {
+	return processWith(prefix, spec, nil)
+}
+
+// ProcessWith is like Process, but consults lookupers in order before
+// falling back to the real process environment. This allows alternate
+// value sources, such as .env files or Docker/Kubernetes secrets, to stand
+// in for (or take priority over) the real environment.
+func ProcessWith(prefix string, spec interface{}, lookupers ...Lookuper) error {
+	return processWith(prefix, spec, lookupers)
+}
+
+// MustProcess is like Process but panics if an error occurs. It is
+// intended for use in init() or other contexts where a misconfigured spec
+// is an unrecoverable error.
+func MustProcess(prefix string, spec interface{}) {
+	if err := Process(prefix, spec); err != nil {
+		panic(err)
+	}
+}
+
+func processWith(prefix string, spec interface{}, lookupers []Lookuper) error {
 	s := reflect.ValueOf(spec).Elem()
 	if s.Kind() != reflect.Struct {
 		return ErrInvalidSpecification
 	}
-	errors := make([]error, 0)
+	errs := make([]error, 0)
 	typeOfSpec := s.Type()
 	for i := 0; i < s.NumField(); i++ {
 		f := s.Field(i)
 		if f.CanSet() {
-			fieldName := typeOfSpec.Field(i).Tag.Get("envconfig")
+			ft := parseFieldTag(typeOfSpec.Field(i).Tag.Get("envconfig"))
+			fieldName := ft.Name
 			if fieldName == "" {
 				continue
 			}
-			key := strings.ToUpper(fmt.Sprintf("%s_%s", prefix, fieldName))
-			value := os.Getenv(key)
+
+			var key string
+			switch {
+			case ft.Alias != "":
+				key = strings.ToUpper(ft.Alias)
+			case prefix == "":
+				key = strings.ToUpper(fieldName)
+			default:
+				key = strings.ToUpper(fmt.Sprintf("%s_%s", prefix, fieldName))
+			}
+			value, ok := lookupValue(lookupers, key)
+
+			if !ok {
+				if path := typeOfSpec.Field(i).Tag.Get("envFile"); path != "" {
+					if contents, err := ioutil.ReadFile(path); err == nil {
+						value = strings.TrimSpace(string(contents))
+						ok = true
+					}
+				}
+			}
 
 			def := typeOfSpec.Field(i).Tag.Get("default")
-			if def != "" && value == "" {
+			if def == "" {
+				def = ft.Default
+			}
+			if def != "" && !ok {
 				value = def
+				ok = true
 			}
 
-			req := typeOfSpec.Field(i).Tag.Get("required")
-			if value == "" && f.Kind() != reflect.Struct {
-				if req == "true" {
-					errors = append(errors, &RequiredError{
+			required := typeOfSpec.Field(i).Tag.Get("required") == "true" || ft.Required
+			if ft.Optional {
+				required = false
+			}
+			if !ok && f.Kind() != reflect.Struct {
+				if required {
+					errs = append(errs, &RequiredError{
 						KeyName: key,
 					})
 				}
 				continue
 			}
 
-			switch f.Kind() {
-			case reflect.String:
-				f.SetString(value)
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				intValue, err := strconv.ParseInt(value, 0, f.Type().Bits())
-				if err != nil {
-					errors = append(errors, &ParseError{
-						KeyName:   key,
-						FieldName: fieldName,
-						TypeName:  f.Type().String(),
-						Value:     value,
-					})
-					continue
-				}
-				f.SetInt(intValue)
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint64:
-				uintValue, err := strconv.ParseUint(value, 0, f.Type().Bits())
+			if handled, err := decode(value, f); handled {
 				if err != nil {
-					errors = append(errors, &ParseError{
+					errs = append(errs, &ParseError{
 						KeyName:   key,
 						FieldName: fieldName,
 						TypeName:  f.Type().String(),
 						Value:     value,
 					})
-					continue
 				}
-				f.SetUint(uintValue)
+				continue
+			}
+
+			switch f.Kind() {
 			case reflect.Struct:
+				nestedPrefix := key
+				if ft.HasPrefix {
+					nestedPrefix = strings.ToUpper(ft.Prefix)
+				}
 				structPtr := reflect.New(f.Type()).Interface()
-				if err := Process(key, structPtr); err != nil {
+				if err := processWith(nestedPrefix, structPtr, lookupers); err != nil {
 					return err
 				}
 				f.Set(reflect.ValueOf(structPtr).Elem())
-			case reflect.Bool:
-				boolValue, err := strconv.ParseBool(value)
-				if err != nil {
-					errors = append(errors, &ParseError{
+			case reflect.Ptr:
+				if t := f.Type().Elem(); t.Kind() == reflect.Struct && t.PkgPath() == "net/url" && t.Name() == "URL" {
+					v, err := url.Parse(value)
+					if err == nil {
+						f.Set(reflect.ValueOf(v))
+					}
+					break
+				}
+				if f.IsNil() {
+					f.Set(reflect.New(f.Type().Elem()))
+				}
+				elem := f.Elem()
+				if handled, err := decode(value, elem); handled {
+					if err != nil {
+						errs = append(errs, &ParseError{
+							KeyName:   key,
+							FieldName: fieldName,
+							TypeName:  f.Type().String(),
+							Value:     value,
+						})
+					}
+					break
+				}
+				if err := setScalar(elem, value); err != nil {
+					errs = append(errs, &ParseError{
 						KeyName:   key,
 						FieldName: fieldName,
 						TypeName:  f.Type().String(),
 						Value:     value,
 					})
-					continue
 				}
-				f.SetBool(boolValue)
-			case reflect.Float32, reflect.Float64:
-				floatValue, err := strconv.ParseFloat(value, f.Type().Bits())
-				if err != nil {
-					errors = append(errors, &ParseError{
+
+			case reflect.Slice:
+				if value == "" {
+					break
+				}
+				sep := typeOfSpec.Field(i).Tag.Get("separator")
+				if sep == "" {
+					sep = ","
+				}
+				parts := strings.Split(value, sep)
+				slice := reflect.MakeSlice(f.Type(), len(parts), len(parts))
+				ok := true
+				for idx, part := range parts {
+					if err := setScalar(slice.Index(idx), part); err != nil {
+						errs = append(errs, &ParseError{
+							KeyName:   key,
+							FieldName: fmt.Sprintf("%s[%d]", fieldName, idx),
+							TypeName:  f.Type().Elem().String(),
+							Value:     part,
+						})
+						ok = false
+					}
+				}
+				if ok {
+					f.Set(slice)
+				}
+			case reflect.Map:
+				if value == "" {
+					break
+				}
+				sep := typeOfSpec.Field(i).Tag.Get("separator")
+				if sep == "" {
+					sep = ","
+				}
+				kvsep := typeOfSpec.Field(i).Tag.Get("kvseparator")
+				if kvsep == "" {
+					kvsep = ":"
+				}
+				pairs := strings.Split(value, sep)
+				m := reflect.MakeMap(f.Type())
+				ok := true
+				for idx, pair := range pairs {
+					kv := strings.SplitN(pair, kvsep, 2)
+					if len(kv) != 2 {
+						errs = append(errs, &ParseError{
+							KeyName:   key,
+							FieldName: fmt.Sprintf("%s[%d]", fieldName, idx),
+							TypeName:  f.Type().String(),
+							Value:     pair,
+						})
+						ok = false
+						continue
+					}
+					mapKey := reflect.New(f.Type().Key()).Elem()
+					if err := setScalar(mapKey, kv[0]); err != nil {
+						errs = append(errs, &ParseError{
+							KeyName:   key,
+							FieldName: fmt.Sprintf("%s[%d]", fieldName, idx),
+							TypeName:  f.Type().Key().String(),
+							Value:     kv[0],
+						})
+						ok = false
+						continue
+					}
+					mapValue := reflect.New(f.Type().Elem()).Elem()
+					if err := setScalar(mapValue, kv[1]); err != nil {
+						errs = append(errs, &ParseError{
+							KeyName:   key,
+							FieldName: fmt.Sprintf("%s[%d]", fieldName, idx),
+							TypeName:  f.Type().Elem().String(),
+							Value:     kv[1],
+						})
+						ok = false
+						continue
+					}
+					m.SetMapIndex(mapKey, mapValue)
+				}
+				if ok {
+					f.Set(m)
+				}
+			default:
+				if err := setScalar(f, value); err != nil {
+					errs = append(errs, &ParseError{
 						KeyName:   key,
 						FieldName: fieldName,
 						TypeName:  f.Type().String(),
@@ -140,21 +285,73 @@ func Process(prefix string, spec interface{}) error {
 					})
 					continue
 				}
-				f.SetFloat(floatValue)
-			case reflect.Ptr:
-				if t := f.Type().Elem(); t.Kind() == reflect.Struct && t.PkgPath() == "net/url" && t.Name() == "URL" {
-					v, err := url.Parse(value)
-					if err == nil {
-						f.Set(reflect.ValueOf(v))
-					}
-				}
-
 			}
 		}
 	}
 
-	if len(errors) > 0 {
-		return MultiError(errors)
+	if len(errs) > 0 {
+		return MultiError(errs)
+	}
+	return nil
+}
+
+// decode attempts to populate field by calling one of the Decoder,
+// encoding.TextUnmarshaler, or encoding.BinaryUnmarshaler interfaces it (or
+// its pointer) implements. The returned bool reports whether field
+// implements one of these interfaces at all; err is only meaningful when it
+// does.
+func decode(value string, field reflect.Value) (bool, error) {
+	if !field.CanAddr() {
+		return false, nil
+	}
+	addr := field.Addr()
+	if !addr.CanInterface() {
+		return false, nil
+	}
+	switch d := addr.Interface().(type) {
+	case Decoder:
+		return true, d.Decode(value)
+	case encoding.TextUnmarshaler:
+		return true, d.UnmarshalText([]byte(value))
+	case encoding.BinaryUnmarshaler:
+		return true, d.UnmarshalBinary([]byte(value))
+	}
+	return false, nil
+}
+
+// setScalar assigns value to field after converting it according to field's
+// kind. It is used both for top-level scalar fields and for the individual
+// elements of slice and map fields.
+func setScalar(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue, err := strconv.ParseInt(value, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(value, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(uintValue)
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolValue)
+	case reflect.Float32, reflect.Float64:
+		floatValue, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(floatValue)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type().String())
 	}
 	return nil
 }
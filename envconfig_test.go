@@ -0,0 +1,221 @@
+package envconfig
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	old, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestProcessSliceAndMap(t *testing.T) {
+	var spec struct {
+		Users      []string       `envconfig:"USERS"`
+		Ports      []int          `envconfig:"PORTS" separator:";"`
+		ColorCodes map[string]int `envconfig:"COLORCODES" kvseparator:":"`
+		U32        uint32         `envconfig:"U32"`
+	}
+
+	setenv(t, "MYAPP_USERS", "rob,ken,robert")
+	setenv(t, "MYAPP_PORTS", "80;443")
+	setenv(t, "MYAPP_COLORCODES", "red:1,green:2,blue:3")
+	setenv(t, "MYAPP_U32", "5")
+
+	if err := Process("myapp", &spec); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if got := spec.Users; len(got) != 3 || got[0] != "rob" || got[2] != "robert" {
+		t.Errorf("Users = %#v", got)
+	}
+	if got := spec.Ports; len(got) != 2 || got[0] != 80 || got[1] != 443 {
+		t.Errorf("Ports = %#v", got)
+	}
+	if got := spec.ColorCodes; got["red"] != 1 || got["green"] != 2 || got["blue"] != 3 {
+		t.Errorf("ColorCodes = %#v", got)
+	}
+	if spec.U32 != 5 {
+		t.Errorf("U32 = %d, want 5", spec.U32)
+	}
+}
+
+func TestProcessEmptySliceAndMapStayNil(t *testing.T) {
+	var spec struct {
+		Users []string       `envconfig:"USERS"`
+		Ports map[string]int `envconfig:"PORTS"`
+	}
+
+	setenv(t, "MYAPP_USERS", "")
+	setenv(t, "MYAPP_PORTS", "")
+
+	if err := Process("myapp", &spec); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if spec.Users != nil {
+		t.Errorf("Users = %#v, want nil", spec.Users)
+	}
+	if spec.Ports != nil {
+		t.Errorf("Ports = %#v, want nil", spec.Ports)
+	}
+}
+
+func TestProcessSliceParseError(t *testing.T) {
+	var spec struct {
+		Ports []int `envconfig:"PORTS"`
+	}
+	setenv(t, "MYAPP_PORTS", "80,nope,443")
+
+	err := Process("myapp", &spec)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	multi, ok := err.(MultiError)
+	if !ok || len(multi) != 1 {
+		t.Fatalf("err = %#v, want a single ParseError", err)
+	}
+	pe, ok := multi[0].(*ParseError)
+	if !ok || pe.FieldName != "PORTS[1]" {
+		t.Errorf("ParseError = %#v, want FieldName PORTS[1]", multi[0])
+	}
+}
+
+type upperCase string
+
+func (u *upperCase) Decode(value string) error {
+	*u = upperCase(strings.ToUpper(value))
+	return nil
+}
+
+func TestProcessDecoder(t *testing.T) {
+	var spec struct {
+		Name upperCase  `envconfig:"NAME"`
+		Tag  *upperCase `envconfig:"TAG"`
+	}
+	setenv(t, "MYAPP_NAME", "robert")
+	setenv(t, "MYAPP_TAG", "blue")
+
+	if err := Process("myapp", &spec); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if spec.Name != "ROBERT" {
+		t.Errorf("Name = %q, want ROBERT", spec.Name)
+	}
+	if spec.Tag == nil || *spec.Tag != "BLUE" {
+		t.Errorf("Tag = %v, want BLUE", spec.Tag)
+	}
+}
+
+func TestUsageAcceptsValueAndPointerSpecs(t *testing.T) {
+	type Spec struct {
+		Name string `envconfig:"NAME" default:"robert" description:"the user's name"`
+	}
+
+	var byPointer bytes.Buffer
+	if err := Usage("myapp", &Spec{}, &byPointer); err != nil {
+		t.Fatalf("Usage(pointer) returned error: %v", err)
+	}
+	if !strings.Contains(byPointer.String(), "MYAPP_NAME") {
+		t.Errorf("Usage(pointer) output missing MYAPP_NAME:\n%s", byPointer.String())
+	}
+
+	var byValue bytes.Buffer
+	if err := Usage("myapp", Spec{}, &byValue); err != nil {
+		t.Fatalf("Usage(value) returned error: %v", err)
+	}
+	if !strings.Contains(byValue.String(), "MYAPP_NAME") {
+		t.Errorf("Usage(value) output missing MYAPP_NAME:\n%s", byValue.String())
+	}
+}
+
+func TestProcessWithMapLookuper(t *testing.T) {
+	var spec struct {
+		Name string `envconfig:"NAME"`
+	}
+	lookuper := MapLookuper{"MYAPP_NAME": "robert"}
+	if err := ProcessWith("myapp", &spec, lookuper); err != nil {
+		t.Fatalf("ProcessWith returned error: %v", err)
+	}
+	if spec.Name != "robert" {
+		t.Errorf("Name = %q, want robert", spec.Name)
+	}
+}
+
+func TestFileLookuperFallsBackToOsEnvForFileSuffix(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/db_pw"
+	if err := os.WriteFile(path, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	setenv(t, "MYAPP_DBPASSWORD_FILE", path)
+
+	var spec struct {
+		DBPassword string `envconfig:"DBPASSWORD"`
+	}
+	lookuper := FileLookuper{MapLookuper{}}
+	if err := ProcessWith("myapp", &spec, lookuper); err != nil {
+		t.Fatalf("ProcessWith returned error: %v", err)
+	}
+	if spec.DBPassword != "s3cr3t" {
+		t.Errorf("DBPassword = %q, want s3cr3t", spec.DBPassword)
+	}
+}
+
+func TestProcessDistinguishesUnsetFromEmpty(t *testing.T) {
+	var spec struct {
+		Name string `envconfig:"NAME" default:"fallback"`
+	}
+	setenv(t, "MYAPP_NAME", "")
+
+	if err := Process("myapp", &spec); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if spec.Name != "" {
+		t.Errorf("Name = %q, want empty string preserved over default", spec.Name)
+	}
+}
+
+func TestProcessTagOptions(t *testing.T) {
+	var spec struct {
+		Name string `envconfig:"NAME,alias=CUSTOM_NAME"`
+		Port int    `envconfig:"PORT,default=9090"`
+	}
+	setenv(t, "CUSTOM_NAME", "robert")
+
+	if err := Process("myapp", &spec); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if spec.Name != "robert" {
+		t.Errorf("Name = %q, want robert (via alias)", spec.Name)
+	}
+	if spec.Port != 9090 {
+		t.Errorf("Port = %d, want 9090 (via default= option)", spec.Port)
+	}
+}
+
+func TestProcessNestedPrefixOverride(t *testing.T) {
+	type Inner struct {
+		Host string `envconfig:"HOST"`
+	}
+	var spec struct {
+		DB Inner `envconfig:"DB,prefix=DATABASE"`
+	}
+	setenv(t, "DATABASE_HOST", "db.internal")
+
+	if err := Process("myapp", &spec); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if spec.DB.Host != "db.internal" {
+		t.Errorf("DB.Host = %q, want db.internal", spec.DB.Host)
+	}
+}
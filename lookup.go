@@ -0,0 +1,111 @@
+package envconfig
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Lookuper is implemented by types that can resolve the value of an
+// environment variable from some source other than the real process
+// environment. ProcessWith consults a list of Lookupers in order, falling
+// back to os.LookupEnv only if none of them recognize the key.
+type Lookuper interface {
+	// Lookup returns the value associated with key and whether it was
+	// found, mirroring the signature of os.LookupEnv.
+	Lookup(key string) (string, bool)
+}
+
+// MapLookuper resolves keys from an in-memory map. It is primarily useful
+// in tests, where setting real environment variables is undesirable.
+type MapLookuper map[string]string
+
+func (l MapLookuper) Lookup(key string) (string, bool) {
+	v, ok := l[key]
+	return v, ok
+}
+
+// OsLookuper resolves keys from the real process environment via
+// os.LookupEnv. It is the Lookuper ProcessWith falls back to implicitly,
+// but is exported so it can be composed explicitly, e.g. wrapped in a
+// FileLookuper.
+type OsLookuper struct{}
+
+func (OsLookuper) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// FileLookuper wraps another Lookuper and additionally honors the
+// Docker/Kubernetes secrets convention: if KEY isn't found, KEY_FILE is
+// looked up instead and, if present, its value is treated as a path whose
+// contents become the resolved value (e.g. DB_PASSWORD_FILE=/run/secrets/db_pw).
+// KEY_FILE is resolved from the wrapped Lookuper first and the real
+// process environment second, so the convention works even when
+// FileLookuper wraps a Lookuper that doesn't itself consult the OS
+// environment.
+type FileLookuper struct {
+	Lookuper
+}
+
+func (l FileLookuper) Lookup(key string) (string, bool) {
+	if v, ok := l.Lookuper.Lookup(key); ok {
+		return v, true
+	}
+	path, ok := l.Lookuper.Lookup(key + "_FILE")
+	if !ok {
+		path, ok = os.LookupEnv(key + "_FILE")
+	}
+	if !ok {
+		return "", false
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(contents)), true
+}
+
+// DotEnvLookuper parses the .env-style file at path, one KEY=VALUE pair
+// per line, and resolves keys from it. Blank lines and lines starting with
+// '#' are ignored, and values may optionally be wrapped in single or
+// double quotes.
+func DotEnvLookuper(path string) (Lookuper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(MapLookuper)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// lookupValue consults lookupers in order, falling back to os.LookupEnv if
+// none of them have the key. Using LookupEnv rather than Getenv preserves
+// the distinction between an explicitly-set empty value and an unset one.
+func lookupValue(lookupers []Lookuper, key string) (string, bool) {
+	for _, l := range lookupers {
+		if v, ok := l.Lookup(key); ok {
+			return v, true
+		}
+	}
+	return OsLookuper{}.Lookup(key)
+}
@@ -0,0 +1,46 @@
+package envconfig
+
+import "strings"
+
+// fieldTag is the result of parsing a struct field's `envconfig` tag,
+// which accepts the combined syntax `envconfig:"NAME,option1,option2"` in
+// addition to the plain `envconfig:"NAME"` form. Recognized options are
+// `required`, `optional`, `default=xxx`, `alias=OTHER_KEY`, and
+// `prefix=OTHER_PREFIX` (the last only meaningful on struct fields).
+//
+// The separately-named `required` and `default` struct tags are still
+// honored for backward compatibility. A non-empty `default` struct tag
+// takes precedence over the `default=` option; a field is required if
+// either the `required:"true"` tag or the `required` option says so, and
+// the `optional` option overrides both.
+type fieldTag struct {
+	Name      string
+	Required  bool
+	Optional  bool
+	Default   string
+	Alias     string
+	Prefix    string
+	HasPrefix bool
+}
+
+func parseFieldTag(tag string) fieldTag {
+	parts := strings.Split(tag, ",")
+	ft := fieldTag{Name: strings.TrimSpace(parts[0])}
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case opt == "required":
+			ft.Required = true
+		case opt == "optional":
+			ft.Optional = true
+		case strings.HasPrefix(opt, "default="):
+			ft.Default = strings.TrimPrefix(opt, "default=")
+		case strings.HasPrefix(opt, "alias="):
+			ft.Alias = strings.TrimPrefix(opt, "alias=")
+		case strings.HasPrefix(opt, "prefix="):
+			ft.Prefix = strings.TrimPrefix(opt, "prefix=")
+			ft.HasPrefix = true
+		}
+	}
+	return ft
+}
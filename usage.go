@@ -0,0 +1,128 @@
+package envconfig
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// DefaultTableFormat is the text/template used by Usage to render the
+// variable listing when no custom format is supplied.
+const DefaultTableFormat = `KEY	TYPE	DEFAULT	REQUIRED	DESCRIPTION
+{{range .}}{{.Key}}	{{.Type}}	{{.Default}}	{{.Required}}	{{.Tags.Get "description"}}
+{{end}}`
+
+// varInfo holds everything Usage needs to know about a single
+// environment-configured struct field.
+type varInfo struct {
+	Key      string
+	Type     reflect.Type
+	Tags     reflect.StructTag
+	Required bool
+	Default  string
+}
+
+// gatherInfo walks spec the same way Process does, collecting a varInfo for
+// every field that carries an envconfig tag. Nested structs are walked with
+// the composed PREFIX_SUB_FIELD key, matching Process's recursion. spec may
+// be a struct or a pointer to one; unlike Process, gatherInfo only reads
+// tags and types, so it never needs spec to be addressable.
+func gatherInfo(prefix string, spec interface{}) ([]varInfo, error) {
+	t := reflect.TypeOf(spec)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, ErrInvalidSpecification
+	}
+
+	infos := make([]varInfo, 0)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := parseFieldTag(sf.Tag.Get("envconfig"))
+		fieldName := tag.Name
+		if fieldName == "" {
+			continue
+		}
+
+		var key string
+		switch {
+		case tag.Alias != "":
+			key = strings.ToUpper(tag.Alias)
+		case prefix == "":
+			key = strings.ToUpper(fieldName)
+		default:
+			key = strings.ToUpper(fmt.Sprintf("%s_%s", prefix, fieldName))
+		}
+
+		ptrType := reflect.PtrTo(sf.Type)
+		decodable := ptrType.Implements(reflect.TypeOf((*Decoder)(nil)).Elem()) ||
+			ptrType.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) ||
+			ptrType.Implements(reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem())
+		if sf.Type.Kind() == reflect.Struct && !decodable {
+			nestedPrefix := key
+			if tag.HasPrefix {
+				nestedPrefix = strings.ToUpper(tag.Prefix)
+			}
+			nested, err := gatherInfo(nestedPrefix, reflect.New(sf.Type).Interface())
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, nested...)
+			continue
+		}
+
+		def := sf.Tag.Get("default")
+		if def == "" {
+			def = tag.Default
+		}
+		required := sf.Tag.Get("required") == "true" || tag.Required
+		if tag.Optional {
+			required = false
+		}
+
+		infos = append(infos, varInfo{
+			Key:      key,
+			Type:     sf.Type,
+			Tags:     sf.Tag,
+			Required: required,
+			Default:  def,
+		})
+	}
+	return infos, nil
+}
+
+// Usage writes a tabular, --help-style summary of every environment
+// variable spec resolves to w: its key, type, default, whether it is
+// required, and its optional `description` tag. Nested structs are
+// reported with the composed PREFIX_SUB_FIELD key, matching Process.
+func Usage(prefix string, spec interface{}, w io.Writer) error {
+	return Usagef(prefix, spec, w, DefaultTableFormat)
+}
+
+// Usagef is like Usage but renders the variable listing through a custom
+// text/template instead of DefaultTableFormat.
+func Usagef(prefix string, spec interface{}, w io.Writer, format string) error {
+	infos, err := gatherInfo(prefix, spec)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("envconfig").Parse(format)
+	if err != nil {
+		return err
+	}
+
+	tabs := tabwriter.NewWriter(w, 1, 0, 4, ' ', 0)
+	if err := tmpl.Execute(tabs, infos); err != nil {
+		return err
+	}
+	return tabs.Flush()
+}